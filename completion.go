@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lassejlv/ls-pretty/internal/complete"
+)
+
+// completionSpec describes ls-pretty's own CLI surface for tab
+// completion: just the bare path argument main.go actually accepts.
+// It has no flags to declare yet - main.go doesn't parse any - so don't
+// advertise completions for ones that don't exist; add Flags entries
+// here once a flag is wired up in main.go.
+func completionSpec() complete.Spec {
+	return complete.Spec{
+		Command: "ls-pretty",
+		Path:    complete.PredictFiles{},
+	}
+}
+
+// runCompletion implements the "completion" subcommand and its hidden
+// "__complete" backend, dispatched from main based on os.Args.
+func runCompletion(args []string) error {
+	spec := completionSpec()
+
+	if len(args) == 0 {
+		return fmt.Errorf("usage: ls-pretty completion <bash|zsh|fish|powershell> [--install|--uninstall]")
+	}
+
+	switch args[0] {
+	case "__complete":
+		fmt.Println(spec.Complete(args[1:]))
+		return nil
+	}
+
+	shell := complete.Shell(args[0])
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("completion: find home dir: %w", err)
+	}
+
+	switch {
+	case len(args) > 1 && args[1] == "--install-completion":
+		return spec.Install(home, shell)
+	case len(args) > 1 && args[1] == "--uninstall-completion":
+		return spec.Uninstall(home, shell)
+	default:
+		script, err := spec.Script(shell)
+		if err != nil {
+			return err
+		}
+		fmt.Print(script)
+		return nil
+	}
+}
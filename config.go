@@ -0,0 +1,28 @@
+package main
+
+// AutocompleteMode controls how much of the keyboard the completion
+// popup is allowed to take over.
+type AutocompleteMode string
+
+const (
+	// AutocompletePassive shows suggestions as you type but leaves every
+	// key routed to the buffer until the user explicitly enters the
+	// popup with the trigger key.
+	AutocompletePassive AutocompleteMode = "passive"
+	// AutocompleteActive is the original behavior: once suggestions are
+	// showing, arrow keys navigate them instead of moving the cursor.
+	AutocompleteActive AutocompleteMode = "active"
+	// AutocompleteManual only shows the popup when the trigger key is
+	// pressed; it never appears automatically while typing.
+	AutocompleteManual AutocompleteMode = "manual"
+)
+
+// Config holds user-facing editor settings.
+type Config struct {
+	AutocompleteMode AutocompleteMode
+}
+
+// DefaultConfig matches the editor's historical behavior.
+func DefaultConfig() Config {
+	return Config{AutocompleteMode: AutocompleteActive}
+}
@@ -0,0 +1,98 @@
+package complete
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// markerFor delimits the block we own inside a user's rc file, so
+// Uninstall can find and remove exactly what Install added.
+func markerFor(command string) (start, end string) {
+	return fmt.Sprintf("# >>> %s completion >>>", command), fmt.Sprintf("# <<< %s completion <<<", command)
+}
+
+// rcFile returns the rc file Install should append to for shell, under home.
+func rcFile(home string, shell Shell) (string, error) {
+	switch shell {
+	case Bash:
+		return filepath.Join(home, ".bashrc"), nil
+	case Zsh:
+		return filepath.Join(home, ".zshrc"), nil
+	case Fish:
+		return filepath.Join(home, ".config", "fish", "config.fish"), nil
+	case PowerShell:
+		return filepath.Join(home, ".config", "powershell", "Microsoft.PowerShell_profile.ps1"), nil
+	default:
+		return "", fmt.Errorf("complete: unsupported shell %q", shell)
+	}
+}
+
+// Install appends the completion script for shell to the user's rc
+// file, replacing any block it previously installed.
+func (s Spec) Install(home string, shell Shell) error {
+	script, err := s.Script(shell)
+	if err != nil {
+		return err
+	}
+	path, err := rcFile(home, shell)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("complete: prepare %s: %w", filepath.Dir(path), err)
+	}
+
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("complete: read %s: %w", path, err)
+	}
+
+	start, end := markerFor(s.Command)
+	content := removeBlock(string(existing), start, end)
+	content = strings.TrimRight(content, "\n")
+	if content != "" {
+		content += "\n"
+	}
+	content += fmt.Sprintf("%s\n%s%s\n", start, script, end)
+
+	return os.WriteFile(path, []byte(content), 0644)
+}
+
+// Uninstall removes the block Install previously added for shell.
+func (s Spec) Uninstall(home string, shell Shell) error {
+	path, err := rcFile(home, shell)
+	if err != nil {
+		return err
+	}
+
+	existing, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("complete: read %s: %w", path, err)
+	}
+
+	start, end := markerFor(s.Command)
+	content := removeBlock(string(existing), start, end)
+	return os.WriteFile(path, []byte(content), 0644)
+}
+
+// removeBlock strips the first start/end-delimited block (inclusive) from content.
+func removeBlock(content, start, end string) string {
+	s := strings.Index(content, start)
+	if s == -1 {
+		return content
+	}
+	e := strings.Index(content[s:], end)
+	if e == -1 {
+		return content
+	}
+	e = s + e + len(end)
+	for e < len(content) && content[e] == '\n' {
+		e++
+	}
+	return content[:s] + content[e:]
+}
@@ -0,0 +1,94 @@
+// Package complete generates and installs shell completion scripts for
+// the ls-pretty binary itself, independent of the in-editor Go
+// completion feature in internal/lsp.
+package complete
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Predictor returns the candidate values for a single flag or
+// positional argument, given the text typed so far.
+type Predictor interface {
+	Predict(prefix string) []string
+}
+
+// PredictFiles predicts paths under the current directory whose name
+// has one of the given extensions (e.g. ".go"). An empty extension list
+// predicts any file.
+type PredictFiles struct {
+	Extensions []string
+}
+
+// Predict implements Predictor.
+func (p PredictFiles) Predict(prefix string) []string {
+	dir, base := filepath.Split(prefix)
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var out []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, base) {
+			continue
+		}
+		if !entry.IsDir() && len(p.Extensions) > 0 && !hasAnyExt(name, p.Extensions) {
+			continue
+		}
+		full := filepath.Join(dir, name)
+		if entry.IsDir() {
+			full += "/"
+		}
+		out = append(out, full)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func hasAnyExt(name string, exts []string) bool {
+	for _, ext := range exts {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// PredictSet predicts from a fixed list of values, e.g. theme names or
+// sort modes for a flag.
+type PredictSet struct {
+	Values []string
+}
+
+// Predict implements Predictor.
+func (p PredictSet) Predict(prefix string) []string {
+	var out []string
+	for _, v := range p.Values {
+		if strings.HasPrefix(v, prefix) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// Flag declares tab-completion behavior for one CLI flag.
+type Flag struct {
+	Name    string
+	Predict Predictor
+}
+
+// Spec describes everything the completion script generator needs: how
+// to predict the bare path argument and how to predict each flag's value.
+type Spec struct {
+	Command string
+	Path    Predictor
+	Flags   []Flag
+}
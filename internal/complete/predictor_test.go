@@ -0,0 +1,64 @@
+package complete
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPredictSet(t *testing.T) {
+	p := PredictSet{Values: []string{"dark", "light", "solarized"}}
+
+	got := p.Predict("da")
+	want := []string{"dark"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Predict(%q) = %v, want %v", "da", got, want)
+	}
+
+	if got := p.Predict(""); len(got) != 3 {
+		t.Fatalf("Predict(\"\") = %v, want all 3 values", got)
+	}
+}
+
+func TestCompleteOnEmptyWordsDoesNotPanic(t *testing.T) {
+	spec := Spec{Command: "ls-pretty", Path: PredictSet{Values: []string{"a.go", "b.go"}}}
+
+	defer func() {
+		if r := recover(); r != nil {
+			t.Fatalf("Complete(nil) panicked: %v", r)
+		}
+	}()
+
+	got := spec.Complete(nil)
+	if got != "a.go\nb.go" {
+		t.Fatalf("Complete(nil) = %q, want %q", got, "a.go\nb.go")
+	}
+}
+
+func TestCompletePredictsFlagValue(t *testing.T) {
+	spec := Spec{
+		Command: "ls-pretty",
+		Path:    PredictSet{},
+		Flags: []Flag{
+			{Name: "--theme", Predict: PredictSet{Values: []string{"dark", "light"}}},
+		},
+	}
+
+	got := spec.Complete([]string{"--theme", "d"})
+	if got != "dark" {
+		t.Fatalf("Complete([--theme d]) = %q, want %q", got, "dark")
+	}
+}
+
+func TestCompletePredictsFlagName(t *testing.T) {
+	spec := Spec{
+		Flags: []Flag{
+			{Name: "--theme", Predict: PredictSet{}},
+			{Name: "--sort", Predict: PredictSet{}},
+		},
+	}
+
+	got := spec.Complete([]string{"--s"})
+	if got != "--sort" {
+		t.Fatalf("Complete([--s]) = %q, want %q", got, "--sort")
+	}
+}
@@ -0,0 +1,134 @@
+package complete
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Shell identifies a supported shell for completion script generation.
+type Shell string
+
+const (
+	Bash       Shell = "bash"
+	Zsh        Shell = "zsh"
+	Fish       Shell = "fish"
+	PowerShell Shell = "powershell"
+)
+
+// Script renders the completion script for shell. The script shells out
+// to "<command> __complete <words...>" so predictors stay in Go rather
+// than being reimplemented per shell.
+func (s Spec) Script(shell Shell) (string, error) {
+	switch shell {
+	case Bash:
+		return s.bashScript(), nil
+	case Zsh:
+		return s.zshScript(), nil
+	case Fish:
+		return s.fishScript(), nil
+	case PowerShell:
+		return s.powershellScript(), nil
+	default:
+		return "", fmt.Errorf("complete: unsupported shell %q", shell)
+	}
+}
+
+func (s Spec) bashScript() string {
+	return fmt.Sprintf(`_%[1]s_complete() {
+  local cur words
+  cur="${COMP_WORDS[COMP_CWORD]}"
+  words=$(%[1]s __complete "${COMP_WORDS[@]:1}")
+  COMPREPLY=($(compgen -W "${words}" -- "${cur}"))
+}
+complete -F _%[1]s_complete %[1]s
+`, s.Command)
+}
+
+func (s Spec) zshScript() string {
+	return fmt.Sprintf(`#compdef %[1]s
+_%[1]s() {
+  local -a words
+  words=("${(@f)$(%[1]s __complete "${words[@]:1}")}")
+  _describe 'command' words
+}
+compdef _%[1]s %[1]s
+`, s.Command)
+}
+
+func (s Spec) fishScript() string {
+	return fmt.Sprintf(`function __%[1]s_complete
+  %[1]s __complete (commandline -opc) (commandline -ct)
+end
+complete -c %[1]s -f -a '(__%[1]s_complete)'
+`, s.Command)
+}
+
+func (s Spec) powershellScript() string {
+	return fmt.Sprintf(`Register-ArgumentCompleter -Native -CommandName %[1]s -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+    & %[1]s __complete $commandAst.ToString().Split()[1..($commandAst.ToString().Split().Length-1)] |
+        ForEach-Object { [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_) }
+}
+`, s.Command)
+}
+
+// Complete is the backend for the hidden "__complete" subcommand each
+// generated script shells out to: given the words typed so far, it
+// returns the newline-joined candidates for the last one.
+func (s Spec) Complete(words []string) string {
+	last := ""
+	if len(words) > 0 {
+		last = words[len(words)-1]
+	}
+
+	if strings.HasPrefix(last, "-") {
+		for _, flag := range s.Flags {
+			if flag.Name == lastFlagFor(words) {
+				return strings.Join(flag.Predict.Predict(""), "\n")
+			}
+		}
+		var names []string
+		for _, flag := range s.Flags {
+			names = append(names, flag.Name)
+		}
+		return strings.Join(namesWithPrefix(names, last), "\n")
+	}
+
+	if len(words) > 0 {
+		if flagName := lastFlagFor(words[:len(words)-1]); flagName != "" {
+			for _, flag := range s.Flags {
+				if flag.Name == flagName {
+					return strings.Join(flag.Predict.Predict(last), "\n")
+				}
+			}
+		}
+	}
+
+	if s.Path != nil {
+		return strings.Join(s.Path.Predict(last), "\n")
+	}
+	return ""
+}
+
+// lastFlagFor returns the most recent flag token in words, so its value
+// can be predicted, or "" if the last token isn't a flag awaiting a value.
+func lastFlagFor(words []string) string {
+	if len(words) == 0 {
+		return ""
+	}
+	last := words[len(words)-1]
+	if strings.HasPrefix(last, "-") {
+		return last
+	}
+	return ""
+}
+
+func namesWithPrefix(names []string, prefix string) []string {
+	var out []string
+	for _, n := range names {
+		if strings.HasPrefix(n, prefix) {
+			out = append(out, n)
+		}
+	}
+	return out
+}
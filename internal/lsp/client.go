@@ -0,0 +1,372 @@
+// Package lsp implements a minimal JSON-RPC 2.0 client for talking to a
+// Go language server (gopls) over stdio. It speaks just enough of the
+// Language Server Protocol to drive editor completion, hover,
+// definition, and signature help.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Position is a zero-based line/character offset, matching the LSP spec.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is an inclusive-exclusive span between two positions.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// CompletionItemKind mirrors the LSP CompletionItemKind enum (subset we care about).
+type CompletionItemKind int
+
+const (
+	KindText CompletionItemKind = iota + 1
+	KindMethod
+	KindFunction
+	KindConstructor
+	KindField
+	KindVariable
+	KindClass
+	KindInterface
+	KindModule
+	KindProperty
+	KindKeyword = 14
+)
+
+// CompletionItem is a single suggestion returned by the server, carrying
+// enough detail for the popup to render a type signature rather than a
+// bare identifier.
+type CompletionItem struct {
+	Label         string             `json:"label"`
+	Kind          CompletionItemKind `json:"kind"`
+	Detail        string             `json:"detail,omitempty"`
+	Documentation string             `json:"documentation,omitempty"`
+	InsertText    string             `json:"insertText,omitempty"`
+	TextEdit      *TextEdit          `json:"textEdit,omitempty"`
+}
+
+// TextEdit is the replace range the server wants applied, used instead of
+// naively splicing InsertText at the cursor.
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+// Client manages a single gopls process for one workspace.
+type Client struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+
+	mu      sync.Mutex
+	nextID  int
+	pending map[int]chan rpcResult
+	capable bool
+}
+
+// rpcResult is what a pending call() is waiting on: either a decoded
+// result or the error that ended the wait (a protocol error from the
+// server, or the connection dying).
+type rpcResult struct {
+	data json.RawMessage
+	err  error
+}
+
+// NewClient spawns gopls for the given workspace root and performs the
+// initialize handshake. If gopls cannot be started, callers should fall
+// back to the static keyword hints rather than treating this as fatal.
+func NewClient(workspaceRoot string) (*Client, error) {
+	cmd := exec.Command("gopls", "-mode=stdio")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("lsp: stdin pipe: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("lsp: stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("lsp: start gopls: %w", err)
+	}
+
+	c := &Client{
+		cmd:     cmd,
+		stdin:   stdin,
+		stdout:  bufio.NewReader(stdout),
+		pending: make(map[int]chan rpcResult),
+	}
+
+	go c.readLoop()
+
+	if _, err := c.call("initialize", map[string]any{
+		"processId": nil,
+		"rootUri":   "file://" + workspaceRoot,
+		"capabilities": map[string]any{
+			"textDocument": map[string]any{
+				"completion": map[string]any{
+					"completionItem": map[string]any{"snippetSupport": true},
+				},
+			},
+		},
+	}); err != nil {
+		cmd.Process.Kill()
+		return nil, fmt.Errorf("lsp: initialize: %w", err)
+	}
+	c.notify("initialized", map[string]any{})
+	c.capable = true
+	return c, nil
+}
+
+// Available reports whether the server handshake succeeded.
+func (c *Client) Available() bool {
+	return c != nil && c.capable
+}
+
+// DidOpen notifies the server that a document is now open in the editor.
+func (c *Client) DidOpen(uri, languageID, text string) {
+	c.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{
+			"uri":        uri,
+			"languageId": languageID,
+			"version":    1,
+			"text":       text,
+		},
+	})
+}
+
+// DidChange notifies the server of a full-document content change.
+func (c *Client) DidChange(uri string, version int, text string) {
+	c.notify("textDocument/didChange", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "version": version},
+		"contentChanges": []map[string]any{
+			{"text": text},
+		},
+	})
+}
+
+// Completion requests completion items at the given position.
+func (c *Client) Completion(uri string, pos Position) ([]CompletionItem, error) {
+	raw, err := c.call("textDocument/completion", map[string]any{
+		"textDocument": map[string]any{"uri": uri},
+		"position":     pos,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Items []CompletionItem `json:"items"`
+	}
+	if err := json.Unmarshal(raw, &result); err == nil && result.Items != nil {
+		return result.Items, nil
+	}
+	var items []CompletionItem
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil, fmt.Errorf("lsp: decode completion: %w", err)
+	}
+	return items, nil
+}
+
+// Hover requests hover information at the given position.
+func (c *Client) Hover(uri string, pos Position) (string, error) {
+	raw, err := c.call("textDocument/hover", map[string]any{
+		"textDocument": map[string]any{"uri": uri},
+		"position":     pos,
+	})
+	if err != nil {
+		return "", err
+	}
+	var result struct {
+		Contents struct {
+			Value string `json:"value"`
+		} `json:"contents"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return "", fmt.Errorf("lsp: decode hover: %w", err)
+	}
+	return result.Contents.Value, nil
+}
+
+// Definition requests the declaration location for the symbol under the cursor.
+func (c *Client) Definition(uri string, pos Position) ([]Location, error) {
+	raw, err := c.call("textDocument/definition", map[string]any{
+		"textDocument": map[string]any{"uri": uri},
+		"position":     pos,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var locs []Location
+	if err := json.Unmarshal(raw, &locs); err != nil {
+		return nil, fmt.Errorf("lsp: decode definition: %w", err)
+	}
+	return locs, nil
+}
+
+// Location is a file range, as returned by definition/references requests.
+type Location struct {
+	URI   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+// SignatureHelp requests active-parameter info for the call under the cursor.
+func (c *Client) SignatureHelp(uri string, pos Position) (string, error) {
+	raw, err := c.call("textDocument/signatureHelp", map[string]any{
+		"textDocument": map[string]any{"uri": uri},
+		"position":     pos,
+	})
+	if err != nil {
+		return "", err
+	}
+	var result struct {
+		Signatures []struct {
+			Label string `json:"label"`
+		} `json:"signatures"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return "", fmt.Errorf("lsp: decode signatureHelp: %w", err)
+	}
+	if len(result.Signatures) == 0 {
+		return "", nil
+	}
+	return result.Signatures[0].Label, nil
+}
+
+// Shutdown performs the LSP shutdown/exit sequence and waits for gopls to exit.
+func (c *Client) Shutdown() error {
+	if c == nil || c.cmd == nil {
+		return nil
+	}
+	if _, err := c.call("shutdown", nil); err != nil {
+		return err
+	}
+	c.notify("exit", nil)
+	c.stdin.Close()
+	return c.cmd.Wait()
+}
+
+type rpcRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      int    `json:"id,omitempty"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	ID     int             `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (c *Client) call(method string, params any) (json.RawMessage, error) {
+	c.mu.Lock()
+	c.nextID++
+	id := c.nextID
+	ch := make(chan rpcResult, 1)
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	if err := c.write(rpcRequest{JSONRPC: "2.0", ID: id, Method: method, Params: params}); err != nil {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+		return nil, err
+	}
+	result := <-ch
+	if result.err != nil {
+		return nil, result.err
+	}
+	return result.data, nil
+}
+
+func (c *Client) notify(method string, params any) {
+	c.write(rpcRequest{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+func (c *Client) write(req rpcRequest) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err = fmt.Fprintf(c.stdin, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
+
+// readLoop decodes Content-Length-framed messages from gopls and
+// dispatches responses to their waiting caller. If gopls dies or the
+// pipe closes, every call() still waiting on a response is unblocked
+// with an error instead of hanging forever.
+func (c *Client) readLoop() {
+	exitErr := io.ErrClosedPipe
+	defer func() { c.failPending(exitErr) }()
+
+	for {
+		var length int
+		for {
+			line, err := c.stdout.ReadString('\n')
+			if err != nil {
+				exitErr = err
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			if line == "" {
+				break
+			}
+			if strings.HasPrefix(line, "Content-Length:") {
+				fmt.Sscanf(strings.TrimSpace(strings.TrimPrefix(line, "Content-Length:")), "%d", &length)
+			}
+		}
+		if length == 0 {
+			continue
+		}
+		buf := make([]byte, length)
+		if _, err := io.ReadFull(c.stdout, buf); err != nil {
+			exitErr = err
+			return
+		}
+		var resp rpcResponse
+		if err := json.Unmarshal(buf, &resp); err != nil {
+			continue
+		}
+
+		result := rpcResult{data: resp.Result}
+		if resp.Error != nil {
+			result = rpcResult{err: fmt.Errorf("lsp: %s (code %d)", resp.Error.Message, resp.Error.Code)}
+		}
+
+		c.mu.Lock()
+		ch, ok := c.pending[resp.ID]
+		if ok {
+			delete(c.pending, resp.ID)
+		}
+		c.mu.Unlock()
+		if ok {
+			ch <- result
+		}
+	}
+}
+
+// failPending unblocks every call() still waiting on a response, used
+// once the read loop can no longer deliver one.
+func (c *Client) failPending(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, ch := range c.pending {
+		ch <- rpcResult{err: err}
+		delete(c.pending, id)
+	}
+}
@@ -0,0 +1,143 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestClient wires up a Client against a pair of pipes instead of a
+// real gopls process, so readLoop/call/failPending can be driven from the
+// test without spawning anything.
+func newTestClient() (c *Client, serverIn *io.PipeReader, serverOut *io.PipeWriter) {
+	stdinR, stdinW := io.Pipe()
+	stdoutR, stdoutW := io.Pipe()
+	c = &Client{
+		stdin:   stdinW,
+		stdout:  bufio.NewReader(stdoutR),
+		pending: make(map[int]chan rpcResult),
+	}
+	go c.readLoop()
+	return c, stdinR, stdoutW
+}
+
+// readFrame reads one Content-Length-framed message off r, the same
+// framing call()/readLoop speak.
+func readFrame(t *testing.T, r io.Reader) []byte {
+	t.Helper()
+	br := bufio.NewReader(r)
+	var length int
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			t.Fatalf("readFrame: header: %v", err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		if strings.HasPrefix(line, "Content-Length:") {
+			fmt.Sscanf(strings.TrimSpace(strings.TrimPrefix(line, "Content-Length:")), "%d", &length)
+		}
+	}
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(br, buf); err != nil {
+		t.Fatalf("readFrame: body: %v", err)
+	}
+	return buf
+}
+
+// writeFrame writes body to w using the same framing.
+func writeFrame(t *testing.T, w io.Writer, body string) {
+	t.Helper()
+	if _, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n%s", len(body), body); err != nil {
+		t.Fatalf("writeFrame: %v", err)
+	}
+}
+
+func TestClientCallRoundTrip(t *testing.T) {
+	c, serverIn, serverOut := newTestClient()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		req := readFrame(t, serverIn)
+		var parsed struct {
+			ID     int    `json:"id"`
+			Method string `json:"method"`
+		}
+		if err := json.Unmarshal(req, &parsed); err != nil {
+			t.Errorf("decode request: %v", err)
+			return
+		}
+		if parsed.Method != "initialize" {
+			t.Errorf("method = %q, want %q", parsed.Method, "initialize")
+		}
+		writeFrame(t, serverOut, fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":{"capabilities":{}}}`, parsed.ID))
+	}()
+
+	raw, err := c.call("initialize", map[string]any{})
+	if err != nil {
+		t.Fatalf("call: %v", err)
+	}
+	var result struct {
+		Capabilities map[string]any `json:"capabilities"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		t.Fatalf("decode result: %v", err)
+	}
+	<-done
+}
+
+func TestClientCallSurfacesProtocolError(t *testing.T) {
+	c, serverIn, serverOut := newTestClient()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		req := readFrame(t, serverIn)
+		var parsed struct{ ID int `json:"id"` }
+		json.Unmarshal(req, &parsed)
+		writeFrame(t, serverOut, fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"error":{"code":-32601,"message":"method not found"}}`, parsed.ID))
+	}()
+
+	_, err := c.call("bogus", nil)
+	if err == nil {
+		t.Fatalf("call: got nil error, want the server's protocol error")
+	}
+	if !strings.Contains(err.Error(), "method not found") {
+		t.Fatalf("call error = %q, want it to mention %q", err, "method not found")
+	}
+	<-done
+}
+
+func TestClientCallUnblocksWhenPipeCloses(t *testing.T) {
+	c, serverIn, serverOut := newTestClient()
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := c.call("shutdown", nil)
+		errCh <- err
+	}()
+
+	// Wait for the request to actually reach the server side (i.e. call()
+	// has registered its pending channel) before killing the connection,
+	// otherwise failPending could run before there's anything to fail.
+	readFrame(t, serverIn)
+
+	// Simulate gopls dying: its stdout closes without ever answering.
+	serverOut.Close()
+
+	select {
+	case err := <-errCh:
+		if err == nil {
+			t.Fatalf("call: got nil error, want one once the connection closed")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("call still blocked after the server pipe closed")
+	}
+}
@@ -0,0 +1,246 @@
+// Package prompt provides modal UIs for destructive or input-driven
+// file operations (delete, rename, chmod, mkdir) so they share one
+// confirm/select/input interaction pattern instead of ad-hoc single-key
+// handlers scattered through the viewer.
+package prompt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// Session holds the single buffered reader a sequence of prompts reads
+// from, so that a prompt awaiting one line never swallows bytes typed
+// ahead for the next one. Callers that issue more than one prompt against
+// the same input stream (the common case for a viewer session) should
+// build one Session and reuse it rather than calling Confirm/Input
+// directly against a raw io.Reader.
+type Session struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// NewSession wraps in in a single bufio.Reader shared by every prompt
+// issued through the returned Session.
+func NewSession(in io.Reader, out io.Writer) *Session {
+	return &Session{in: bufio.NewReader(in), out: out}
+}
+
+// Reader exposes the Session's shared bufio.Reader, for callers that
+// need to read raw key presses from the same stream between prompts
+// (e.g. a viewer's key-handling loop) without losing buffered input.
+func (s *Session) Reader() *bufio.Reader {
+	return s.in
+}
+
+// Confirm renders a yes/no line with a default and reads a single line
+// of input, accepting "y", "n", or an empty line (which takes def).
+func (s *Session) Confirm(question string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	fmt.Fprintf(s.out, "%s [%s] ", question, hint)
+
+	line, err := readLine(s.in)
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "":
+		return def, nil
+	case "y", "yes":
+		return true, nil
+	case "n", "no":
+		return false, nil
+	default:
+		return def, nil
+	}
+}
+
+// MaxHeight is the default number of items Select shows at once before
+// scrolling.
+const MaxHeight = 10
+
+// Select shows a scrollable, fuzzy-filtered list of choices and returns
+// the one the user picked.
+type Select struct {
+	Choices   []string
+	MaxHeight int
+}
+
+// NewSelect builds a Select over choices using the default MaxHeight.
+func NewSelect(choices []string) *Select {
+	return &Select{Choices: choices, MaxHeight: MaxHeight}
+}
+
+// Filter narrows Choices down to those fuzzy-matching query, preserving order.
+func (s *Select) Filter(query string) []string {
+	if query == "" {
+		return s.Choices
+	}
+	var out []string
+	for _, c := range s.Choices {
+		if fuzzyMatch(query, c) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// fuzzyMatch reports whether every rune of query appears in target in
+// order, case-insensitively.
+func fuzzyMatch(query, target string) bool {
+	query = strings.ToLower(query)
+	target = strings.ToLower(target)
+	i := 0
+	for _, r := range target {
+		if i == len(query) {
+			break
+		}
+		if r == rune(query[i]) {
+			i++
+		}
+	}
+	return i == len(query)
+}
+
+// Visible returns at most MaxHeight entries from filtered, starting at offset.
+func (s *Select) Visible(filtered []string, offset int) []string {
+	max := s.MaxHeight
+	if max <= 0 {
+		max = MaxHeight
+	}
+	if offset >= len(filtered) {
+		return nil
+	}
+	end := offset + max
+	if end > len(filtered) {
+		end = len(filtered)
+	}
+	return filtered[offset:end]
+}
+
+// Navigation keys recognized while a Select prompt has focus. Arrow keys
+// arrive as multi-byte terminal escape sequences that this package's
+// one-rune-at-a-time reader doesn't decode, so Select borrows the
+// Emacs-style Ctrl-N/Ctrl-P bindings instead.
+const (
+	keyBackspace = 0x7f
+	keyDelete    = 0x08
+	keyNext      = 0x0e // Ctrl-N
+	keyPrev      = 0x10 // Ctrl-P
+)
+
+// Select shows choices, narrows them as the user types a fuzzy filter,
+// and returns the one they land on. Typed runes extend the filter query,
+// backspace edits it, Ctrl-N/Ctrl-P move the highlighted entry, and
+// Enter accepts it.
+func (s *Session) Select(question string, choices []string) (string, error) {
+	sel := NewSelect(choices)
+	query := ""
+	index := 0
+
+	for {
+		filtered := sel.Filter(query)
+		if index >= len(filtered) {
+			index = len(filtered) - 1
+		}
+		if index < 0 {
+			index = 0
+		}
+		s.renderSelect(question, query, sel, filtered, index)
+
+		r, _, err := s.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		switch r {
+		case '\r', '\n':
+			if len(filtered) == 0 {
+				fmt.Fprintln(s.out, "  no matches")
+				continue
+			}
+			return filtered[index], nil
+		case keyBackspace, keyDelete:
+			if query != "" {
+				_, size := utf8.DecodeLastRuneInString(query)
+				query = query[:len(query)-size]
+			}
+		case keyNext:
+			index++
+		case keyPrev:
+			index--
+		default:
+			query += string(r)
+			index = 0
+		}
+	}
+}
+
+// renderSelect prints the filter query and the currently visible window
+// of choices, marking the highlighted one.
+func (s *Session) renderSelect(question, query string, sel *Select, filtered []string, index int) {
+	fmt.Fprintf(s.out, "%s: %s\n", question, query)
+
+	max := sel.MaxHeight
+	if max <= 0 {
+		max = MaxHeight
+	}
+	offset := 0
+	if index >= max {
+		offset = index - max + 1
+	}
+	for i, c := range sel.Visible(filtered, offset) {
+		marker := "  "
+		if offset+i == index {
+			marker = "> "
+		}
+		fmt.Fprintf(s.out, "%s%s\n", marker, c)
+	}
+}
+
+// Validator checks a candidate input value, returning an error message
+// to display when it's invalid.
+type Validator func(value string) error
+
+// Input reads a line of text, re-prompting while validate rejects it.
+func (s *Session) Input(question string, validate Validator) (string, error) {
+	for {
+		fmt.Fprintf(s.out, "%s: ", question)
+		line, err := s.in.ReadString('\n')
+		if err != nil && line == "" {
+			return "", err
+		}
+		value := strings.TrimRight(line, "\r\n")
+
+		if validate != nil {
+			if err := validate(value); err != nil {
+				fmt.Fprintf(s.out, "  %v\n", err)
+				continue
+			}
+		}
+		return value, nil
+	}
+}
+
+// NotExists is an Input validator rejecting a path that already exists,
+// for prompts like "create new file/dir".
+func NotExists(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+	return nil
+}
+
+func readLine(in *bufio.Reader) (string, error) {
+	line, err := in.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return line, nil
+}
@@ -0,0 +1,131 @@
+package prompt
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSessionSharesReaderAcrossCalls(t *testing.T) {
+	session := NewSession(strings.NewReader("y\nnewname\n"), &bytes.Buffer{})
+
+	ok, err := session.Confirm("delete?", false)
+	if err != nil {
+		t.Fatalf("Confirm: %v", err)
+	}
+	if !ok {
+		t.Fatalf("Confirm: got false, want true")
+	}
+
+	name, err := session.Input("rename to", nil)
+	if err != nil {
+		t.Fatalf("Input: %v (buffered input from Confirm was dropped)", err)
+	}
+	if name != "newname" {
+		t.Fatalf("Input: got %q, want %q", name, "newname")
+	}
+}
+
+func TestInputRevalidates(t *testing.T) {
+	session := NewSession(strings.NewReader("\nbad.go\nok.go\n"), &bytes.Buffer{})
+
+	validate := func(value string) error {
+		if value == "" {
+			return errors.New("must not be empty")
+		}
+		if value == "bad.go" {
+			return errors.New("name is reserved")
+		}
+		return nil
+	}
+
+	value, err := session.Input("name", validate)
+	if err != nil {
+		t.Fatalf("Input: %v", err)
+	}
+	if value != "ok.go" {
+		t.Fatalf("Input: got %q, want %q", value, "ok.go")
+	}
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	cases := []struct {
+		query, target string
+		want          bool
+	}{
+		{"mn", "main.go", true},
+		{"main", "main.go", true},
+		{"xyz", "main.go", false},
+		{"", "main.go", true},
+	}
+	for _, c := range cases {
+		if got := fuzzyMatch(c.query, c.target); got != c.want {
+			t.Errorf("fuzzyMatch(%q, %q) = %v, want %v", c.query, c.target, got, c.want)
+		}
+	}
+}
+
+func TestSelectVisible(t *testing.T) {
+	sel := &Select{Choices: []string{"a", "b", "c", "d", "e"}, MaxHeight: 2}
+	filtered := sel.Filter("")
+
+	if got := sel.Visible(filtered, 0); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Fatalf("Visible(0): got %v", got)
+	}
+	if got := sel.Visible(filtered, 4); len(got) != 1 || got[0] != "e" {
+		t.Fatalf("Visible(4): got %v", got)
+	}
+	if got := sel.Visible(filtered, 10); got != nil {
+		t.Fatalf("Visible(10): got %v, want nil", got)
+	}
+}
+
+func TestSessionSelectFiltersAsUserTypes(t *testing.T) {
+	// "ma" narrows to main.go, then Enter accepts it.
+	session := NewSession(strings.NewReader("ma\n"), &bytes.Buffer{})
+
+	got, err := session.Select("pick", []string{"main.go", "viewer.go", "keys.go"})
+	if err != nil {
+		t.Fatalf("Select: %v", err)
+	}
+	if got != "main.go" {
+		t.Fatalf("Select: got %q, want %q", got, "main.go")
+	}
+}
+
+func TestSessionSelectNavigatesWithCtrlN(t *testing.T) {
+	// Ctrl-N moves off the top match before Enter accepts the highlighted one.
+	session := NewSession(strings.NewReader("\x0e\n"), &bytes.Buffer{})
+
+	got, err := session.Select("pick", []string{"a.go", "b.go", "c.go"})
+	if err != nil {
+		t.Fatalf("Select: %v", err)
+	}
+	if got != "b.go" {
+		t.Fatalf("Select: got %q, want %q", got, "b.go")
+	}
+}
+
+func TestSessionSelectBackspaceWidensFilter(t *testing.T) {
+	// Typing "mz" matches nothing; backspacing "z" back to "m" should
+	// widen the filter again so Enter can accept a match.
+	session := NewSession(strings.NewReader("mz\x7f\n"), &bytes.Buffer{})
+
+	got, err := session.Select("pick", []string{"main.go", "viewer.go"})
+	if err != nil {
+		t.Fatalf("Select: %v", err)
+	}
+	if got != "main.go" {
+		t.Fatalf("Select: got %q, want %q", got, "main.go")
+	}
+}
+
+func TestNotExists(t *testing.T) {
+	if err := NotExists("/nonexistent/path/for/ls-pretty-tests"); err != nil {
+		t.Fatalf("NotExists on missing path: %v", err)
+	}
+	if err := NotExists("."); err == nil {
+		t.Fatalf("NotExists on existing path: got nil error")
+	}
+}
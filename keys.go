@@ -0,0 +1,133 @@
+package main
+
+import "github.com/lassejlv/ls-pretty/internal/lsp"
+
+// Key identifies a single key event delivered to the editor.
+type Key int
+
+const (
+	KeyRune Key = iota
+	KeyEnter
+	KeySpace
+	KeyTab
+	KeyArrowUp
+	KeyArrowDown
+	KeyCtrlSpace
+	KeyEscape
+)
+
+// KeyEvent is a key press, with Rune set when Key == KeyRune.
+type KeyEvent struct {
+	Key  Key
+	Rune rune
+}
+
+// HandleKey routes a key event either to the buffer or to the
+// completion popup, depending on AutocompleteMode and whether the popup
+// currently has focus. The popup must never steal Enter, Space, or the
+// arrow keys from a fast typist unless they've explicitly moved into it.
+func (e *Editor) HandleKey(ev KeyEvent) {
+	if e.toPopup(ev) {
+		e.handlePopupKey(ev)
+		return
+	}
+	e.handleBufferKey(ev)
+}
+
+// toPopup decides whether ev should go to the popup instead of the buffer.
+func (e *Editor) toPopup(ev KeyEvent) bool {
+	if len(e.suggestions) == 0 {
+		return false
+	}
+
+	switch e.config.AutocompleteMode {
+	case AutocompleteActive:
+		return true
+	case AutocompletePassive:
+		if ev.Key == KeyCtrlSpace || ev.Key == KeyTab {
+			e.popupFocused = true
+		}
+		return e.popupFocused
+	case AutocompleteManual:
+		return e.popupFocused
+	default:
+		return false
+	}
+}
+
+func (e *Editor) handlePopupKey(ev KeyEvent) {
+	switch ev.Key {
+	case KeyArrowUp:
+		e.selectedIndex--
+		if e.selectedIndex < 0 {
+			e.selectedIndex = len(e.suggestions) - 1
+		}
+	case KeyArrowDown:
+		e.selectedIndex++
+		if e.selectedIndex >= len(e.suggestions) {
+			e.selectedIndex = 0
+		}
+	case KeyEnter, KeyTab:
+		item := e.suggestions[e.selectedIndex]
+		offset, _ := e.ApplyCompletion(e.cursor, item)
+		e.cursor = offset
+		e.clearSuggestions()
+	case KeyEscape:
+		e.clearSuggestions()
+	default:
+		e.handleBufferKey(ev)
+	}
+}
+
+func (e *Editor) handleBufferKey(ev KeyEvent) {
+	switch ev.Key {
+	case KeyRune:
+		e.buf = e.buf[:e.cursor] + string(ev.Rune) + e.buf[e.cursor:]
+		e.cursor++
+		e.syncSuggestions()
+	case KeyEnter:
+		e.buf = e.buf[:e.cursor] + "\n" + e.buf[e.cursor:]
+		e.cursor++
+	case KeySpace:
+		e.buf = e.buf[:e.cursor] + " " + e.buf[e.cursor:]
+		e.cursor++
+		e.syncSuggestions()
+	case KeyCtrlSpace:
+		e.setSuggestions(e.Completions(e.cursor))
+		if len(e.suggestions) > 0 {
+			e.popupFocused = true
+		}
+	}
+}
+
+// syncSuggestions keeps the popup from outliving the buffer state it was
+// computed against. In passive/active mode it just recomputes against
+// the new cursor, same as before. Manual mode never auto-shows a popup
+// while typing, but a Ctrl-Space-focused one can still be present when a
+// rune or space reaches this method through handlePopupKey's default
+// case - if so, drop focus and clear the now-stale suggestions rather
+// than let a later Enter/Tab apply one of them against the wrong
+// document state (the same TextEdit-range corruption chunk0-2 fixed).
+func (e *Editor) syncSuggestions() {
+	if e.config.AutocompleteMode != AutocompleteManual {
+		e.setSuggestions(e.Completions(e.cursor))
+		return
+	}
+	if e.popupFocused {
+		e.clearSuggestions()
+	}
+}
+
+// setSuggestions replaces the popup's candidates and resets the
+// selection to the top one, since the previous selectedIndex is
+// meaningless against a new list.
+func (e *Editor) setSuggestions(items []lsp.CompletionItem) {
+	e.suggestions = items
+	e.selectedIndex = 0
+}
+
+func (e *Editor) clearSuggestions() {
+	e.suggestions = nil
+	e.selectedIndex = 0
+	e.popupFocused = false
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/lassejlv/ls-pretty/internal/lsp"
+)
+
+func newTestEditor(mode AutocompleteMode) *Editor {
+	return &Editor{
+		path:   "test.go",
+		buf:    "",
+		config: Config{AutocompleteMode: mode},
+	}
+}
+
+func TestArrowKeysCycleSelectedIndex(t *testing.T) {
+	e := newTestEditor(AutocompleteActive)
+	e.setSuggestions([]lsp.CompletionItem{{Label: "a"}, {Label: "b"}, {Label: "c"}})
+
+	e.HandleKey(KeyEvent{Key: KeyArrowDown})
+	if e.selectedIndex != 1 {
+		t.Fatalf("after one ArrowDown: selectedIndex = %d, want 1", e.selectedIndex)
+	}
+	e.HandleKey(KeyEvent{Key: KeyArrowDown})
+	e.HandleKey(KeyEvent{Key: KeyArrowDown})
+	if e.selectedIndex != 0 {
+		t.Fatalf("ArrowDown should wrap past the end: selectedIndex = %d, want 0", e.selectedIndex)
+	}
+	e.HandleKey(KeyEvent{Key: KeyArrowUp})
+	if e.selectedIndex != 2 {
+		t.Fatalf("ArrowUp should wrap before the start: selectedIndex = %d, want 2", e.selectedIndex)
+	}
+}
+
+func TestEnterAppliesTheSelectedSuggestionNotJustTheFirst(t *testing.T) {
+	e := newTestEditor(AutocompleteActive)
+	e.setSuggestions([]lsp.CompletionItem{{Label: "aaa"}, {Label: "bbb"}})
+	e.HandleKey(KeyEvent{Key: KeyArrowDown})
+
+	e.HandleKey(KeyEvent{Key: KeyEnter})
+
+	if e.buf != "bbb" {
+		t.Fatalf("buf = %q, want %q", e.buf, "bbb")
+	}
+	if len(e.suggestions) != 0 {
+		t.Fatalf("suggestions should be cleared after accepting one, got %v", e.suggestions)
+	}
+}
+
+func TestActiveModeAlwaysRoutesToPopup(t *testing.T) {
+	e := newTestEditor(AutocompleteActive)
+	e.setSuggestions([]lsp.CompletionItem{{Label: "x"}})
+
+	e.HandleKey(KeyEvent{Key: KeyEnter})
+
+	if e.buf != "x" {
+		t.Fatalf("active mode should have routed Enter to the popup, buf = %q", e.buf)
+	}
+}
+
+func TestPassiveModeDoesNotStealEnterUntilFocused(t *testing.T) {
+	e := newTestEditor(AutocompletePassive)
+	e.setSuggestions([]lsp.CompletionItem{{Label: "x"}})
+
+	e.HandleKey(KeyEvent{Key: KeyEnter})
+
+	if e.buf != "\n" {
+		t.Fatalf("passive mode should route unfocused Enter to the buffer, buf = %q", e.buf)
+	}
+	if len(e.suggestions) != 1 {
+		t.Fatalf("suggestions should survive an unfocused Enter, got %v", e.suggestions)
+	}
+}
+
+func TestPassiveModeTabEntersThenAcceptsPopup(t *testing.T) {
+	e := newTestEditor(AutocompletePassive)
+	e.setSuggestions([]lsp.CompletionItem{{Label: "x"}})
+
+	e.HandleKey(KeyEvent{Key: KeyTab})
+
+	if !e.popupFocused && len(e.suggestions) != 0 {
+		t.Fatalf("Tab should either focus or accept the popup, got popupFocused=%v suggestions=%v", e.popupFocused, e.suggestions)
+	}
+	if e.buf != "x" {
+		t.Fatalf("Tab should have accepted the suggestion, buf = %q", e.buf)
+	}
+}
+
+func TestManualModeNeverShowsPopupWhileTyping(t *testing.T) {
+	e := newTestEditor(AutocompleteManual)
+	e.handleBufferKey(KeyEvent{Key: KeyRune, Rune: 'f'})
+
+	if len(e.suggestions) != 0 {
+		t.Fatalf("manual mode should not populate suggestions from typing, got %v", e.suggestions)
+	}
+}
+
+// TestManualModeDropsStaleSuggestionsOnFurtherTyping reproduces the bug
+// where Ctrl-Space focused the popup, the user kept typing (ordinary
+// runes, not popup navigation), and a later Enter still applied the
+// suggestion computed before that typing - splicing a completion against
+// an offset/buffer state that no longer existed.
+func TestManualModeDropsStaleSuggestionsOnFurtherTyping(t *testing.T) {
+	e := newTestEditor(AutocompleteManual)
+	e.HandleKey(KeyEvent{Key: KeyCtrlSpace})
+	e.setSuggestions([]lsp.CompletionItem{{Label: "x"}})
+	e.popupFocused = true
+
+	e.HandleKey(KeyEvent{Key: KeyRune, Rune: 'a'})
+
+	if e.buf != "a" {
+		t.Fatalf("buf = %q, want %q", e.buf, "a")
+	}
+	if len(e.suggestions) != 0 || e.popupFocused {
+		t.Fatalf("typing after Ctrl-Space should drop the stale popup, got suggestions=%v popupFocused=%v", e.suggestions, e.popupFocused)
+	}
+
+	e.HandleKey(KeyEvent{Key: KeyEnter})
+	if e.buf != "a\n" {
+		t.Fatalf("Enter should have inserted a newline, not replayed the stale suggestion; buf = %q", e.buf)
+	}
+}
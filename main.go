@@ -0,0 +1,372 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lassejlv/ls-pretty/internal/lsp"
+)
+
+// keywordHints is the old hard-coded completion table. It now only fires
+// when gopls couldn't be started for the current workspace.
+var keywordHints = map[string]string{
+	"f":  "func",
+	"i":  "if",
+	"fo": "for",
+}
+
+// keyQuit (Ctrl-C) ends an editor session; it's read as a raw rune
+// before HandleKey ever sees it, the same way Viewer.Run special-cases 'q'.
+const keyQuit = '\x03'
+
+// Editor holds the state for the in-app Go file editor, including the
+// optional LSP connection used to drive completion, hover, definition,
+// and signature help.
+type Editor struct {
+	path     string
+	buf      string
+	cursor   int
+	version  int
+	lsp      *lsp.Client
+	lspError error
+
+	config        Config
+	suggestions   []lsp.CompletionItem
+	selectedIndex int
+	popupFocused  bool
+}
+
+// NewEditor opens path for editing and attempts to start gopls for the
+// enclosing workspace. A failure to start gopls is not fatal: the editor
+// falls back to keywordHints and reports the LSP status as unavailable.
+func NewEditor(path string) (*Editor, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("editor: read %s: %w", path, err)
+	}
+
+	e := &Editor{path: path, buf: string(data), version: 1, config: DefaultConfig()}
+
+	root := filepath.Dir(path)
+	client, err := lsp.NewClient(root)
+	if err != nil {
+		e.lspError = err
+	} else {
+		e.lsp = client
+		e.lsp.DidOpen("file://"+path, "go", e.buf)
+	}
+	return e, nil
+}
+
+// lspStatus renders the header indicator: a green dot when gopls is wired
+// up, red when we fell back to keyword hints.
+func (e *Editor) lspStatus() string {
+	if e.lsp.Available() {
+		return "\U0001F7E2 LSP"
+	}
+	return "\U0001F534 LSP (fallback)"
+}
+
+// Completions returns suggestions for the given cursor offset, preferring
+// gopls and falling back to the static keyword table when the server is
+// unavailable or errors.
+func (e *Editor) Completions(offset int) []lsp.CompletionItem {
+	if e.lsp.Available() {
+		pos := e.positionAt(offset)
+		items, err := e.lsp.Completion("file://"+e.path, pos)
+		if err == nil {
+			return items
+		}
+	}
+	return e.fallbackCompletions(offset)
+}
+
+// fallbackCompletions matches the trailing identifier before offset
+// against keywordHints, used only when gopls isn't available.
+func (e *Editor) fallbackCompletions(offset int) []lsp.CompletionItem {
+	prefix := e.wordBefore(offset)
+	if prefix == "" {
+		return nil
+	}
+	word, ok := keywordHints[prefix]
+	if !ok {
+		return nil
+	}
+	return []lsp.CompletionItem{
+		{Label: word, Kind: lsp.KindKeyword, InsertText: word},
+	}
+}
+
+func (e *Editor) wordBefore(offset int) string {
+	start := offset
+	for start > 0 && isIdentByte(e.buf[start-1]) {
+		start--
+	}
+	return e.buf[start:offset]
+}
+
+func isIdentByte(b byte) bool {
+	return b == '_' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
+
+// positionAt converts a byte offset into the buffer into an LSP
+// line/character position. The LSP spec (and gopls, since this client
+// never negotiates otherwise) defines Character as a count of UTF-16
+// code units from the start of the line, not bytes, so any multi-byte
+// rune on the line needs utf16Units rather than a raw byte count.
+func (e *Editor) positionAt(offset int) lsp.Position {
+	line := strings.Count(e.buf[:offset], "\n")
+	lastNL := strings.LastIndex(e.buf[:offset], "\n")
+	return lsp.Position{Line: line, Character: utf16Units(e.buf[lastNL+1 : offset])}
+}
+
+// utf16Units counts the UTF-16 code units s would encode to: one for
+// runes in the Basic Multilingual Plane, two (a surrogate pair) for
+// anything above it.
+func utf16Units(s string) int {
+	n := 0
+	for _, r := range s {
+		if r > 0xFFFF {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+// Run reads key presses from in, feeding each to HandleKey and rendering
+// the buffer (and completion popup, if focused) to out after every one.
+// It mirrors Viewer.Run: one shared reader, one key at a time, until the
+// stream is exhausted or the user sends keyQuit.
+func (e *Editor) Run(in io.Reader, out io.Writer) error {
+	fmt.Fprintln(out, e.lspStatus())
+	reader := bufio.NewReader(in)
+	e.render(out)
+	for {
+		r, _, err := reader.ReadRune()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if r == keyQuit {
+			return nil
+		}
+		e.HandleKey(decodeKey(r, reader))
+		e.render(out)
+	}
+}
+
+// decodeKey turns a rune off the wire into a KeyEvent, reading the rest
+// of a terminal escape sequence from reader when one starts.
+func decodeKey(r rune, reader *bufio.Reader) KeyEvent {
+	switch r {
+	case '\r', '\n':
+		return KeyEvent{Key: KeyEnter}
+	case ' ':
+		return KeyEvent{Key: KeySpace}
+	case '\t':
+		return KeyEvent{Key: KeyTab}
+	case 0:
+		return KeyEvent{Key: KeyCtrlSpace}
+	case '\x1b':
+		return decodeEscape(reader)
+	default:
+		return KeyEvent{Key: KeyRune, Rune: r}
+	}
+}
+
+// decodeEscape reads the "[X" tail of an arrow-key escape sequence
+// (ESC [ A/B), falling back to a bare KeyEscape for anything else or a
+// sequence cut short by EOF.
+func decodeEscape(reader *bufio.Reader) KeyEvent {
+	bracket, _, err := reader.ReadRune()
+	if err != nil || bracket != '[' {
+		return KeyEvent{Key: KeyEscape}
+	}
+	dir, _, err := reader.ReadRune()
+	if err != nil {
+		return KeyEvent{Key: KeyEscape}
+	}
+	switch dir {
+	case 'A':
+		return KeyEvent{Key: KeyArrowUp}
+	case 'B':
+		return KeyEvent{Key: KeyArrowDown}
+	default:
+		return KeyEvent{Key: KeyEscape}
+	}
+}
+
+// render prints the buffer and, if the completion popup is showing,
+// its suggestions with the selected one marked.
+func (e *Editor) render(out io.Writer) {
+	fmt.Fprintf(out, "\n%s\n", e.buf)
+	if len(e.suggestions) == 0 {
+		return
+	}
+	fmt.Fprintln(out, "-- suggestions --")
+	for i, item := range e.suggestions {
+		marker := "  "
+		if i == e.selectedIndex {
+			marker = "> "
+		}
+		label := item.Label
+		if item.Detail != "" {
+			label = fmt.Sprintf("%s %s", label, item.Detail)
+		}
+		fmt.Fprintf(out, "%s%s\n", marker, label)
+	}
+}
+
+// Close shuts down the gopls connection, if one was started.
+func (e *Editor) Close() error {
+	if e.lsp == nil {
+		return nil
+	}
+	return e.lsp.Shutdown()
+}
+
+// ApplyCompletion inserts item at offset. If the item carries a TextEdit
+// range, that range is deleted first so the server's own replace window
+// (not a naive splice at the cursor) decides what gets overwritten -
+// otherwise accepting "TestFoo" after typing "func TestFoo" would leave
+// "func TestFoo(t *testing.T)TestFoo" behind. Snippet placeholders like
+// ${1:name} are stripped to their default text and their spans recorded
+// as tab stops for the caller to cycle through.
+func (e *Editor) ApplyCompletion(offset int, item lsp.CompletionItem) (newOffset int, tabStops []TabStop) {
+	text := item.InsertText
+	if text == "" {
+		text = item.Label
+	}
+
+	start, end := offset, offset
+	if item.TextEdit != nil {
+		start = e.offsetAt(item.TextEdit.Range.Start)
+		end = e.offsetAt(item.TextEdit.Range.End)
+		text = item.TextEdit.NewText
+	}
+
+	plain, tabStops := parseSnippet(text)
+
+	e.buf = e.buf[:start] + plain + e.buf[end:]
+	e.version++
+	if e.lsp.Available() {
+		e.lsp.DidChange("file://"+e.path, e.version, e.buf)
+	}
+
+	for i := range tabStops {
+		tabStops[i].Start += start
+		tabStops[i].End += start
+	}
+	return start + len(plain), tabStops
+}
+
+// TabStop is a snippet placeholder span (e.g. ${1:name}) in the buffer,
+// in the order the user should tab through them.
+type TabStop struct {
+	Index int
+	Start int
+	End   int
+}
+
+// parseSnippet strips ${N:default} placeholders from an LSP snippet
+// string, returning the plain text a user would see and the tab stops
+// at their offsets within that plain text.
+func parseSnippet(text string) (plain string, stops []TabStop) {
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		if text[i] == '$' && i+1 < len(text) && text[i+1] == '{' {
+			end := strings.IndexByte(text[i:], '}')
+			if end == -1 {
+				b.WriteByte(text[i])
+				i++
+				continue
+			}
+			body := text[i+2 : i+end]
+			idxStr, def, _ := strings.Cut(body, ":")
+			var index int
+			fmt.Sscanf(idxStr, "%d", &index)
+			start := b.Len()
+			b.WriteString(def)
+			stops = append(stops, TabStop{Index: index, Start: start, End: b.Len()})
+			i += end + 1
+			continue
+		}
+		b.WriteByte(text[i])
+		i++
+	}
+	return b.String(), stops
+}
+
+// offsetAt converts an LSP line/character position back into a byte
+// offset into the buffer, the inverse of positionAt: it walks to the
+// start of pos.Line, then re-encodes runes one at a time to find the
+// byte offset pos.Character UTF-16 units into that line.
+func (e *Editor) offsetAt(pos lsp.Position) int {
+	offset := 0
+	line := 0
+	for line < pos.Line {
+		idx := strings.IndexByte(e.buf[offset:], '\n')
+		if idx == -1 {
+			return len(e.buf)
+		}
+		offset += idx + 1
+		line++
+	}
+
+	units := 0
+	for i, r := range e.buf[offset:] {
+		if units >= pos.Character || r == '\n' {
+			return offset + i
+		}
+		if r > 0xFFFF {
+			units += 2
+		} else {
+			units++
+		}
+	}
+	return len(e.buf)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println("usage: ls-pretty <file>")
+		os.Exit(1)
+	}
+
+	if os.Args[1] == "completion" {
+		if err := runCompletion(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if info, err := os.Stat(os.Args[1]); err == nil && info.IsDir() {
+		viewer := NewViewer(os.Args[1], os.Stdin, os.Stdout)
+		if err := viewer.Run(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	editor, err := NewEditor(os.Args[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer editor.Close()
+
+	if err := editor.Run(os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
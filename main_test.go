@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lassejlv/ls-pretty/internal/lsp"
+)
+
+func TestParseSnippetStripsPlaceholders(t *testing.T) {
+	plain, stops := parseSnippet("Println(${1:a})")
+	if plain != "Println(a)" {
+		t.Fatalf("plain = %q, want %q", plain, "Println(a)")
+	}
+	want := []TabStop{{Index: 1, Start: 8, End: 9}}
+	if !reflect.DeepEqual(stops, want) {
+		t.Fatalf("stops = %+v, want %+v", stops, want)
+	}
+}
+
+func TestParseSnippetMultiplePlaceholders(t *testing.T) {
+	plain, stops := parseSnippet("${1:name} ${2:value}")
+	if plain != "name value" {
+		t.Fatalf("plain = %q, want %q", plain, "name value")
+	}
+	if len(stops) != 2 {
+		t.Fatalf("got %d stops, want 2", len(stops))
+	}
+	if stops[0].Index != 1 || stops[1].Index != 2 {
+		t.Fatalf("stops = %+v, want indices 1 then 2", stops)
+	}
+}
+
+func TestParseSnippetNoPlaceholders(t *testing.T) {
+	plain, stops := parseSnippet("plain text")
+	if plain != "plain text" || stops != nil {
+		t.Fatalf("plain = %q, stops = %v, want %q, nil", plain, stops, "plain text")
+	}
+}
+
+// TestApplyCompletionUsesTextEditRangeNotCursor reproduces the gopls
+// "func TestFoo(t *testing.T)TestFoo" bug: completing at the cursor
+// while the server's replace range actually starts earlier (it wants to
+// replace the whole "func TestFoo" already typed) must not leave the
+// old text behind.
+func TestApplyCompletionUsesTextEditRangeNotCursor(t *testing.T) {
+	e := &Editor{buf: "func TestFoo", path: "x_test.go"}
+	cursor := len(e.buf)
+
+	item := lsp.CompletionItem{
+		TextEdit: &lsp.TextEdit{
+			Range:   lsp.Range{Start: lsp.Position{Line: 0, Character: 5}, End: lsp.Position{Line: 0, Character: 12}},
+			NewText: "TestFoo(t *testing.T)",
+		},
+	}
+
+	newOffset, _ := e.ApplyCompletion(cursor, item)
+
+	want := "func TestFoo(t *testing.T)"
+	if e.buf != want {
+		t.Fatalf("buf = %q, want %q", e.buf, want)
+	}
+	if newOffset != len(want) {
+		t.Fatalf("newOffset = %d, want %d", newOffset, len(want))
+	}
+}
+
+func TestApplyCompletionWithoutTextEditInsertsAtCursor(t *testing.T) {
+	e := &Editor{buf: "fmt.", path: "x.go"}
+	item := lsp.CompletionItem{InsertText: "Println"}
+
+	newOffset, _ := e.ApplyCompletion(len(e.buf), item)
+
+	if e.buf != "fmt.Println" {
+		t.Fatalf("buf = %q, want %q", e.buf, "fmt.Println")
+	}
+	if newOffset != len("fmt.Println") {
+		t.Fatalf("newOffset = %d, want %d", newOffset, len("fmt.Println"))
+	}
+}
+
+func TestApplyCompletionTabStopsAreOffsetByReplaceStart(t *testing.T) {
+	e := &Editor{buf: "xx", path: "x.go"}
+	item := lsp.CompletionItem{
+		TextEdit: &lsp.TextEdit{
+			Range:   lsp.Range{Start: lsp.Position{Line: 0, Character: 0}, End: lsp.Position{Line: 0, Character: 2}},
+			NewText: "call(${1:arg})",
+		},
+	}
+
+	_, stops := e.ApplyCompletion(2, item)
+
+	if len(stops) != 1 {
+		t.Fatalf("got %d tab stops, want 1", len(stops))
+	}
+	if e.buf[stops[0].Start:stops[0].End] != "arg" {
+		t.Fatalf("tab stop span = %q, want %q", e.buf[stops[0].Start:stops[0].End], "arg")
+	}
+}
+
+// TestRunFeedsKeystrokesIntoTheBuffer reproduces what ran main.go
+// previously never did: typing into a live editor session actually
+// reaches e.buf instead of main printing the LSP status and exiting.
+func TestRunFeedsKeystrokesIntoTheBuffer(t *testing.T) {
+	e := &Editor{path: "x.go"}
+	var out bytes.Buffer
+
+	if err := e.Run(strings.NewReader("hi\x03"), &out); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if e.buf != "hi" {
+		t.Fatalf("buf = %q, want %q", e.buf, "hi")
+	}
+}
+
+func TestRunStopsAtEOFWithoutKeyQuit(t *testing.T) {
+	e := &Editor{path: "x.go"}
+	var out bytes.Buffer
+
+	if err := e.Run(strings.NewReader("ok"), &out); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if e.buf != "ok" {
+		t.Fatalf("buf = %q, want %q", e.buf, "ok")
+	}
+}
+
+// TestPositionAtUsesUTF16UnitsNotBytes reproduces the bug where a
+// multi-byte rune earlier on the line threw off every later column: "é"
+// is 2 bytes in UTF-8 but 1 UTF-16 unit, so the character after it must
+// report column 2, not 3.
+func TestPositionAtUsesUTF16UnitsNotBytes(t *testing.T) {
+	e := &Editor{buf: "// café\nx"}
+
+	pos := e.positionAt(len("// café"))
+	want := lsp.Position{Line: 0, Character: len("// caf") + 1}
+	if pos != want {
+		t.Fatalf("positionAt = %+v, want %+v", pos, want)
+	}
+}
+
+// TestOffsetAtIsTheInverseOfPositionAtWithMultiByteRunes round-trips a
+// position through positionAt/offsetAt on a line containing a rune
+// outside the BMP (an emoji, needing a UTF-16 surrogate pair) to confirm
+// they agree on the same unit.
+func TestOffsetAtIsTheInverseOfPositionAtWithMultiByteRunes(t *testing.T) {
+	e := &Editor{buf: "x := \"😀ok\""}
+	offset := len(e.buf)
+
+	pos := e.positionAt(offset)
+	if got := e.offsetAt(pos); got != offset {
+		t.Fatalf("offsetAt(positionAt(%d)) = %d, want %d", offset, got, offset)
+	}
+}
+
+func TestDecodeKeyMapsArrowEscapeSequence(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader("[A"))
+	if got := decodeEscape(reader); got.Key != KeyArrowUp {
+		t.Fatalf("decodeEscape(\"[A\") = %+v, want KeyArrowUp", got)
+	}
+
+	reader = bufio.NewReader(strings.NewReader("[B"))
+	if got := decodeEscape(reader); got.Key != KeyArrowDown {
+		t.Fatalf("decodeEscape(\"[B\") = %+v, want KeyArrowDown", got)
+	}
+
+	reader = bufio.NewReader(strings.NewReader("x"))
+	if got := decodeEscape(reader); got.Key != KeyEscape {
+		t.Fatalf("decodeEscape(\"x\") = %+v, want KeyEscape", got)
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/lassejlv/ls-pretty/internal/prompt"
+)
+
+// Viewer is the file-browsing side of ls-pretty, as opposed to the
+// Editor used once a file is opened for editing. Its keybindings are:
+//
+//	d  delete the selected entry (with confirmation)
+//	r  rename the selected entry
+//	m  chmod the selected entry
+//	n  create a new file
+//	N  create a new directory
+type Viewer struct {
+	dir      string
+	selected string
+	prompts  *prompt.Session
+}
+
+// NewViewer opens a viewer rooted at dir, reading prompt input from in
+// and writing prompts/output to out. in/out are wrapped in a single
+// prompt.Session so a sequence of key presses, each issuing its own
+// prompt, shares one buffered reader instead of losing look-ahead input
+// between prompts.
+func NewViewer(dir string, in io.Reader, out io.Writer) *Viewer {
+	return &Viewer{dir: dir, prompts: prompt.NewSession(in, out)}
+}
+
+// HandleKey dispatches a viewer keybinding. 'p' picks the entry that
+// subsequent keybindings operate on; the rest act on whatever 'p' last
+// selected.
+func (v *Viewer) HandleKey(ev KeyEvent) error {
+	if ev.Key != KeyRune {
+		return nil
+	}
+	switch ev.Rune {
+	case 'p':
+		entries, err := v.entries()
+		if err != nil {
+			return err
+		}
+		picked, err := v.PickFile(entries)
+		if err != nil {
+			return err
+		}
+		v.selected = picked
+		return nil
+	case 'd':
+		return v.Delete()
+	case 'r':
+		return v.Rename()
+	case 'm':
+		return v.Chmod()
+	case 'n':
+		return v.NewEntry(false)
+	case 'N':
+		return v.NewEntry(true)
+	default:
+		return nil
+	}
+}
+
+// entries lists the names of everything directly inside the viewer's directory.
+func (v *Viewer) entries() ([]string, error) {
+	des, err := os.ReadDir(v.dir)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(des))
+	for i, d := range des {
+		names[i] = d.Name()
+	}
+	return names, nil
+}
+
+// requireSelected refuses to act when 'p' hasn't picked an entry yet.
+// Without this, filepath.Join(v.dir, "") resolves to v.dir itself, so
+// Delete/Rename/Chmod would silently operate on the directory being
+// browsed instead of an entry inside it.
+func (v *Viewer) requireSelected() error {
+	if v.selected == "" {
+		return fmt.Errorf("no entry selected; press p to pick one first")
+	}
+	return nil
+}
+
+// Delete confirms and removes the selected entry. It routes through
+// prompt.Session.Confirm rather than a raw single-key handler so the
+// default answer, and the accepted y/N/enter forms, stay consistent with
+// every other destructive action.
+func (v *Viewer) Delete() error {
+	if err := v.requireSelected(); err != nil {
+		return err
+	}
+	ok, err := v.prompts.Confirm(fmt.Sprintf("Delete %q?", v.selected), false)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	return os.RemoveAll(filepath.Join(v.dir, v.selected))
+}
+
+// Rename prompts for a new name, rejecting one that already exists, and
+// renames the selected entry.
+func (v *Viewer) Rename() error {
+	if err := v.requireSelected(); err != nil {
+		return err
+	}
+	newName, err := v.prompts.Input(fmt.Sprintf("Rename %q to", v.selected), func(value string) error {
+		if value == "" {
+			return fmt.Errorf("name must not be empty")
+		}
+		return prompt.NotExists(filepath.Join(v.dir, value))
+	})
+	if err != nil {
+		return err
+	}
+	return os.Rename(filepath.Join(v.dir, v.selected), filepath.Join(v.dir, newName))
+}
+
+// Chmod prompts for an octal mode and applies it to the selected entry.
+func (v *Viewer) Chmod() error {
+	if err := v.requireSelected(); err != nil {
+		return err
+	}
+	modeStr, err := v.prompts.Input(fmt.Sprintf("chmod %q to", v.selected), func(value string) error {
+		var mode uint32
+		if _, err := fmt.Sscanf(value, "%o", &mode); err != nil || mode > 0777 {
+			return fmt.Errorf("enter an octal mode like 644 or 755")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	var mode uint32
+	fmt.Sscanf(modeStr, "%o", &mode)
+	return os.Chmod(filepath.Join(v.dir, v.selected), os.FileMode(mode))
+}
+
+// NewEntry prompts for a name and creates either a file or a directory
+// in the current viewer directory.
+func (v *Viewer) NewEntry(dir bool) error {
+	name, err := v.prompts.Input("New name", func(value string) error {
+		if value == "" {
+			return fmt.Errorf("name must not be empty")
+		}
+		return prompt.NotExists(filepath.Join(v.dir, value))
+	})
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(v.dir, name)
+	if dir {
+		return os.Mkdir(path, 0755)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// Run reads one key at a time from the viewer's input until the user
+// presses 'q' or the stream is exhausted, dispatching each to HandleKey.
+func (v *Viewer) Run(out io.Writer) error {
+	reader := v.prompts.Reader()
+	for {
+		r, _, err := reader.ReadRune()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if r == 'q' {
+			return nil
+		}
+		if err := v.HandleKey(KeyEvent{Key: KeyRune, Rune: r}); err != nil {
+			fmt.Fprintln(out, err)
+		}
+	}
+}
+
+// PickFile lets the user fuzzy-filter the entries in dir and returns the
+// chosen one, e.g. for an "open file" action.
+func (v *Viewer) PickFile(entries []string) (string, error) {
+	if len(entries) == 0 {
+		return "", fmt.Errorf("no entries to pick from")
+	}
+	return v.prompts.Select("Pick a file", entries)
+}
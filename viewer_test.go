@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDeleteWithoutSelectionRefuses(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "keep.txt"), []byte("x"), 0644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	v := NewViewer(dir, strings.NewReader(""), &bytes.Buffer{})
+	if err := v.Delete(); err == nil {
+		t.Fatalf("Delete with no prior 'p' selection: got nil error, want refusal")
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Fatalf("Delete with no selection removed the viewer's directory: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "keep.txt")); err != nil {
+		t.Fatalf("Delete with no selection removed a file it shouldn't have touched: %v", err)
+	}
+}
+
+func TestRenameWithoutSelectionRefuses(t *testing.T) {
+	dir := t.TempDir()
+
+	v := NewViewer(dir, strings.NewReader("newname\n"), &bytes.Buffer{})
+	if err := v.Rename(); err == nil {
+		t.Fatalf("Rename with no prior 'p' selection: got nil error, want refusal")
+	}
+}
+
+func TestChmodWithoutSelectionRefuses(t *testing.T) {
+	dir := t.TempDir()
+
+	v := NewViewer(dir, strings.NewReader("644\n"), &bytes.Buffer{})
+	if err := v.Chmod(); err == nil {
+		t.Fatalf("Chmod with no prior 'p' selection: got nil error, want refusal")
+	}
+}
+
+func TestHandleKeyPicksBeforeDeleting(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("x"), 0644); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	// 'p' filters down to "a.txt" and picks it, then 'd' confirms its deletion.
+	v := NewViewer(dir, strings.NewReader("a\ny\n"), &bytes.Buffer{})
+
+	if err := v.HandleKey(KeyEvent{Key: KeyRune, Rune: 'p'}); err != nil {
+		t.Fatalf("HandleKey('p'): %v", err)
+	}
+	if v.selected != "a.txt" {
+		t.Fatalf("selected = %q, want %q", v.selected, "a.txt")
+	}
+
+	if err := v.HandleKey(KeyEvent{Key: KeyRune, Rune: 'd'}); err != nil {
+		t.Fatalf("HandleKey('d'): %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a.txt")); !os.IsNotExist(err) {
+		t.Fatalf("a.txt still exists after delete: %v", err)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Fatalf("viewer directory was removed: %v", err)
+	}
+}
+
+func TestPickFileReturnsErrorOnEmptyDir(t *testing.T) {
+	v := NewViewer(t.TempDir(), strings.NewReader(""), &bytes.Buffer{})
+	if _, err := v.PickFile(nil); err == nil {
+		t.Fatalf("PickFile(nil): got nil error, want one")
+	}
+}